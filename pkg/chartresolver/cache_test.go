@@ -0,0 +1,72 @@
+package chartresolver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// scratchResolver simulates a resolver (such as GitResolver) that hands back
+// a freshly created, caller-owned scratch directory on every call.
+type scratchResolver struct {
+	calls   int
+	lastDir string
+}
+
+func (sr *scratchResolver) Resolve(ctx context.Context, ref Ref) (ResolvedChart, error) {
+	sr.calls++
+	dir, err := os.MkdirTemp("", "scratch-*")
+	if err != nil {
+		return ResolvedChart{}, err
+	}
+	sr.lastDir = dir
+	if err := os.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte("name: mychart\n"), 0644); err != nil {
+		return ResolvedChart{}, err
+	}
+	return ResolvedChart{Path: dir, Digest: "fixed-digest", Ref: ref}, nil
+}
+
+func TestCachingResolverCopiesRatherThanMoves(t *testing.T) {
+	cacheDir := t.TempDir()
+	next := &scratchResolver{}
+	cr, err := NewCachingResolver(next, cacheDir)
+	if err != nil {
+		t.Fatalf("NewCachingResolver returned unexpected error: %v", err)
+	}
+
+	ref := Ref{Repo: "myorg/myrepo", Chart: "mychart", Version: "1.0.0"}
+	resolved, err := cr.Resolve(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("Resolve returned unexpected error: %v", err)
+	}
+	if resolved.Path != filepath.Join(cacheDir, "fixed-digest") {
+		t.Fatalf("expected resolved path inside cache dir, got: %v", resolved.Path)
+	}
+
+	if _, err := os.Stat(filepath.Join(resolved.Path, "Chart.yaml")); err != nil {
+		t.Fatalf("expected Chart.yaml in cached path: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(next.lastDir, "Chart.yaml")); err != nil {
+		t.Fatalf("expected Chart.yaml to remain in the underlying resolver's dir: %v", err)
+	}
+
+	if next.calls != 1 {
+		t.Fatalf("expected 1 call to underlying resolver, got %v", next.calls)
+	}
+
+	// Resolving the same ref again should hit the cache: the underlying
+	// resolver is still called (this resolver doesn't cache the fetch
+	// itself), but the existing cached copy must not be touched or
+	// recreated in a way that fails.
+	resolved2, err := cr.Resolve(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("second Resolve returned unexpected error: %v", err)
+	}
+	if resolved2.Path != resolved.Path {
+		t.Fatalf("expected same cached path on second resolve, got: %v", resolved2.Path)
+	}
+	if _, err := os.Stat(filepath.Join(resolved2.Path, "Chart.yaml")); err != nil {
+		t.Fatalf("expected Chart.yaml in cached path after second resolve: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package chartresolver
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// copyDir recursively copies the contents of src into dst, creating dst if
+// it does not already exist; files already in dst under the same names are
+// overwritten. It is used to lift a chart out of resolver-owned state (a
+// long-lived git clone's working tree, a shared cache directory) into a
+// location the caller fully owns, so that moving or mutating the result
+// doesn't corrupt that state.
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode())
+		}
+		return copyFile(path, target, info.Mode())
+	})
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return errors.Wrapf(err, "error opening source file: %v", src)
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return errors.Wrapf(err, "error creating destination file: %v", dst)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return errors.Wrapf(err, "error copying %v to %v", src, dst)
+	}
+	return nil
+}
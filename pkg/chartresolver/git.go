@@ -0,0 +1,120 @@
+package chartresolver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dollarshaveclub/acyl/pkg/config"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/pkg/errors"
+)
+
+// GitResolver resolves charts by cloning (or updating an existing clone of)
+// the chart's source repository and checking out ref.Version, which may be a
+// tag, branch or commit SHA. ref.Chart is the path within the repository to
+// the chart directory (e.g. "charts/foo").
+type GitResolver struct {
+	cfg     config.GithubConfig
+	workDir string
+}
+
+// NewGitResolver constructs a GitResolver that clones repositories under
+// workDir, authenticating with cfg.Token when set.
+func NewGitResolver(cfg config.GithubConfig, workDir string) *GitResolver {
+	return &GitResolver{cfg: cfg, workDir: workDir}
+}
+
+// Resolve implements ChartResolver.
+func (gr *GitResolver) Resolve(ctx context.Context, ref Ref) (ResolvedChart, error) {
+	repoDir := filepath.Join(gr.workDir, sanitizeForPath(ref.Repo))
+	var auth transport.AuthMethod
+	if gr.cfg.Token != "" {
+		auth = &http.BasicAuth{Username: "x-access-token", Password: gr.cfg.Token}
+	}
+
+	repo, err := gogit.PlainOpen(repoDir)
+	switch {
+	case errors.Is(err, gogit.ErrRepositoryNotExists):
+		cloneURL := fmt.Sprintf("https://github.com/%v.git", ref.Repo)
+		repo, err = gogit.PlainCloneContext(ctx, repoDir, false, &gogit.CloneOptions{
+			URL:  cloneURL,
+			Auth: auth,
+		})
+		if err != nil {
+			return ResolvedChart{}, errors.Wrapf(err, "error cloning chart repo: %v", ref.Repo)
+		}
+	case err != nil:
+		return ResolvedChart{}, errors.Wrapf(err, "error opening chart repo clone: %v", repoDir)
+	default:
+		if err := fetchAll(ctx, repo, auth); err != nil {
+			return ResolvedChart{}, errors.Wrapf(err, "error updating chart repo clone: %v", ref.Repo)
+		}
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return ResolvedChart{}, errors.Wrap(err, "error getting repo worktree")
+	}
+	hash, err := resolveRevision(repo, ref.Version)
+	if err != nil {
+		return ResolvedChart{}, errors.Wrapf(err, "error resolving chart ref version: %v", ref.Version)
+	}
+	if err := wt.Checkout(&gogit.CheckoutOptions{Hash: hash}); err != nil {
+		return ResolvedChart{}, errors.Wrapf(err, "error checking out chart ref version: %v", ref.Version)
+	}
+
+	chartPath := filepath.Join(repoDir, ref.Chart)
+	if _, err := os.Stat(chartPath); err != nil {
+		return ResolvedChart{}, errors.Wrapf(err, "chart path not found in repo: %v", ref.Chart)
+	}
+
+	// repoDir is a long-lived clone reused across calls (and potentially
+	// concurrent resolutions); copy the chart out into a scratch directory the
+	// caller owns rather than handing back a path inside it, so a subsequent
+	// checkout (or a caller mutating/moving the returned path, as
+	// CachingResolver used to) can't corrupt or race with the shared clone.
+	if err := os.MkdirAll(gr.workDir, 0755); err != nil {
+		return ResolvedChart{}, errors.Wrap(err, "error creating chart work dir")
+	}
+	scratchDir, err := os.MkdirTemp(gr.workDir, "chart-*")
+	if err != nil {
+		return ResolvedChart{}, errors.Wrap(err, "error creating chart scratch dir")
+	}
+	if err := copyDir(chartPath, scratchDir); err != nil {
+		return ResolvedChart{}, errors.Wrapf(err, "error copying chart out of repo clone: %v", ref.Chart)
+	}
+
+	return ResolvedChart{Path: scratchDir, Ref: ref}, nil
+}
+
+func fetchAll(ctx context.Context, repo *gogit.Repository, auth transport.AuthMethod) error {
+	err := repo.FetchContext(ctx, &gogit.FetchOptions{Auth: auth, Force: true})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return err
+	}
+	return nil
+}
+
+func resolveRevision(repo *gogit.Repository, version string) (plumbing.Hash, error) {
+	h, err := repo.ResolveRevision(plumbing.Revision(version))
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return *h, nil
+}
+
+func sanitizeForPath(s string) string {
+	out := make([]rune, 0, len(s))
+	for _, r := range s {
+		if r == '/' {
+			r = '_'
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
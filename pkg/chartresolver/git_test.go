@@ -0,0 +1,112 @@
+package chartresolver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dollarshaveclub/acyl/pkg/config"
+	gogit "github.com/go-git/go-git/v5"
+	gogitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// setupLocalChartRepo creates a non-bare git repo at dir containing
+// chartSubdir/Chart.yaml, committed and tagged as version, with a
+// self-referencing "origin" remote so GitResolver's fetch step has
+// something to talk to without needing network access.
+func setupLocalChartRepo(t *testing.T, dir, chartSubdir, version string) {
+	t.Helper()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("error initializing test repo: %v", err)
+	}
+	chartDir := filepath.Join(dir, chartSubdir)
+	if err := os.MkdirAll(chartDir, 0755); err != nil {
+		t.Fatalf("error creating chart dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte("name: mychart\nversion: "+version+"\n"), 0644); err != nil {
+		t.Fatalf("error writing Chart.yaml: %v", err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("error getting worktree: %v", err)
+	}
+	if _, err := wt.Add("."); err != nil {
+		t.Fatalf("error staging files: %v", err)
+	}
+	hash, err := wt.Commit("initial chart", &gogit.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("error committing: %v", err)
+	}
+	if _, err := repo.CreateTag(version, hash, nil); err != nil {
+		t.Fatalf("error tagging: %v", err)
+	}
+	if _, err := repo.CreateRemote(&gogitconfig.RemoteConfig{Name: "origin", URLs: []string{dir}}); err != nil {
+		t.Fatalf("error creating origin remote: %v", err)
+	}
+}
+
+func TestGitResolverCopiesChartOutOfSharedClone(t *testing.T) {
+	scratchRoot := t.TempDir()
+	gr := NewGitResolver(config.GithubConfig{}, scratchRoot)
+	repoParent := filepath.Join(scratchRoot, sanitizeForPath("myorg/myrepo"))
+	setupLocalChartRepo(t, repoParent, "mychart", "1.0.0")
+
+	ref := Ref{Repo: "myorg/myrepo", Chart: "mychart", Version: "1.0.0"}
+	resolved, err := gr.Resolve(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("Resolve returned unexpected error: %v", err)
+	}
+
+	if resolved.Path == filepath.Join(repoParent, "mychart") {
+		t.Fatalf("Resolve returned a path inside the shared clone: %v", resolved.Path)
+	}
+	if _, err := os.Stat(filepath.Join(resolved.Path, "Chart.yaml")); err != nil {
+		t.Fatalf("expected Chart.yaml in resolved path: %v", err)
+	}
+
+	// The chart must still be present in the clone after Resolve returns, so
+	// a second resolution (or a concurrent one) of the same ref still works.
+	if _, err := os.Stat(filepath.Join(repoParent, "mychart", "Chart.yaml")); err != nil {
+		t.Fatalf("chart was removed from the shared clone: %v", err)
+	}
+
+	// Resolving again should succeed and hand back a second, independent
+	// scratch copy rather than reusing or touching the first.
+	resolved2, err := gr.Resolve(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("second Resolve returned unexpected error: %v", err)
+	}
+	if resolved2.Path == resolved.Path {
+		t.Fatalf("expected a fresh scratch dir on second resolve, got same path: %v", resolved2.Path)
+	}
+	if _, err := os.Stat(filepath.Join(resolved.Path, "Chart.yaml")); err != nil {
+		t.Fatalf("first resolve's scratch copy should still exist: %v", err)
+	}
+}
+
+func TestResolveRevisionAndSanitize(t *testing.T) {
+	dir := t.TempDir()
+	setupLocalChartRepo(t, dir, "mychart", "2.0.0")
+	repo, err := gogit.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("error opening test repo: %v", err)
+	}
+	hash, err := resolveRevision(repo, "2.0.0")
+	if err != nil {
+		t.Fatalf("resolveRevision returned unexpected error: %v", err)
+	}
+	if hash == plumbing.ZeroHash {
+		t.Fatal("resolveRevision returned zero hash")
+	}
+
+	if got := sanitizeForPath("myorg/myrepo"); got != "myorg_myrepo" {
+		t.Fatalf("sanitizeForPath() = %v, expected myorg_myrepo", got)
+	}
+}
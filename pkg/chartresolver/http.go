@@ -0,0 +1,123 @@
+package chartresolver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// HTTPResolver resolves charts from a traditional HTTP(S) chart repository
+// (ref.Repo is the repository's base URL), parsing its index.yaml to find
+// the download URL for ref.Chart at ref.Version.
+type HTTPResolver struct {
+	httpClient *http.Client
+	workDir    string
+}
+
+// NewHTTPResolver constructs an HTTPResolver that downloads charts into
+// workDir using client (or http.DefaultClient if nil).
+func NewHTTPResolver(client *http.Client, workDir string) *HTTPResolver {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPResolver{httpClient: client, workDir: workDir}
+}
+
+// chartRepoIndex is the subset of a Helm chart repository's index.yaml this
+// resolver needs.
+type chartRepoIndex struct {
+	Entries map[string][]struct {
+		Version string   `yaml:"version"`
+		URLs    []string `yaml:"urls"`
+		Digest  string   `yaml:"digest"`
+	} `yaml:"entries"`
+}
+
+// Resolve implements ChartResolver.
+func (hr *HTTPResolver) Resolve(ctx context.Context, ref Ref) (ResolvedChart, error) {
+	index, err := hr.fetchIndex(ctx, ref.Repo)
+	if err != nil {
+		return ResolvedChart{}, err
+	}
+	versions, ok := index.Entries[ref.Chart]
+	if !ok {
+		return ResolvedChart{}, fmt.Errorf("chart %v not found in repository index: %v", ref.Chart, ref.Repo)
+	}
+	var url, digest string
+	for _, v := range versions {
+		if v.Version == ref.Version && len(v.URLs) > 0 {
+			url, digest = v.URLs[0], v.Digest
+			break
+		}
+	}
+	if url == "" {
+		return ResolvedChart{}, fmt.Errorf("chart %v@%v not found in repository index: %v", ref.Chart, ref.Version, ref.Repo)
+	}
+
+	tgzPath := filepath.Join(hr.workDir, fmt.Sprintf("%v-%v.tgz", ref.Chart, ref.Version))
+	if err := hr.download(ctx, url, tgzPath); err != nil {
+		return ResolvedChart{}, err
+	}
+	chartPath := filepath.Join(hr.workDir, fmt.Sprintf("%v-%v", ref.Chart, ref.Version))
+	if err := unpackChart(tgzPath, chartPath); err != nil {
+		return ResolvedChart{}, errors.Wrap(err, "error unpacking chart")
+	}
+	return ResolvedChart{Path: chartPath, Digest: digest, Ref: ref}, nil
+}
+
+func (hr *HTTPResolver) fetchIndex(ctx context.Context, repoURL string) (*chartRepoIndex, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, repoURL+"/index.yaml", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "error building index.yaml request")
+	}
+	resp, err := hr.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error fetching index.yaml: %v", repoURL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status fetching index.yaml: %v: %v", repoURL, resp.Status)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading index.yaml")
+	}
+	var idx chartRepoIndex
+	if err := yaml.Unmarshal(b, &idx); err != nil {
+		return nil, errors.Wrapf(err, "error parsing index.yaml: %v", repoURL)
+	}
+	return &idx, nil
+}
+
+func (hr *HTTPResolver) download(ctx context.Context, url, destPath string) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return errors.Wrapf(err, "error building chart download request: %v", url)
+	}
+	resp, err := hr.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "error downloading chart: %v", url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status downloading chart: %v: %v", url, resp.Status)
+	}
+	f, err := os.Create(destPath)
+	if err != nil {
+		return errors.Wrapf(err, "error creating chart file: %v", destPath)
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		return errors.Wrapf(err, "error writing chart file: %v", destPath)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package chartresolver
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// packTestChart builds a minimal .tgz Helm chart archive (just a Chart.yaml)
+// under a top-level directory named name, as required by Helm's chart loader.
+func packTestChart(t *testing.T, name, version string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	contents := []byte("apiVersion: v2\nname: " + name + "\nversion: " + version + "\n")
+	hdr := &tar.Header{
+		Name: name + "/Chart.yaml",
+		Mode: 0644,
+		Size: int64(len(contents)),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		t.Fatalf("error writing tar header: %v", err)
+	}
+	if _, err := tw.Write(contents); err != nil {
+		t.Fatalf("error writing tar contents: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("error closing tar writer: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("error closing gzip writer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestHTTPResolverResolve(t *testing.T) {
+	chartBytes := packTestChart(t, "mychart", "1.0.0")
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	mux.HandleFunc("/index.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`entries:
+  mychart:
+    - version: 1.0.0
+      urls:
+        - "` + srv.URL + `/mychart-1.0.0.tgz"
+      digest: "deadbeef"
+`))
+	})
+	mux.HandleFunc("/mychart-1.0.0.tgz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(chartBytes)
+	})
+
+	workDir := t.TempDir()
+	hr := NewHTTPResolver(srv.Client(), workDir)
+
+	resolved, err := hr.Resolve(context.Background(), Ref{Repo: srv.URL, Chart: "mychart", Version: "1.0.0"})
+	if err != nil {
+		t.Fatalf("Resolve returned unexpected error: %v", err)
+	}
+	if resolved.Digest != "deadbeef" {
+		t.Fatalf("expected digest deadbeef, got: %v", resolved.Digest)
+	}
+	if _, err := os.Stat(filepath.Join(resolved.Path, "Chart.yaml")); err != nil {
+		t.Fatalf("expected Chart.yaml in resolved path: %v", err)
+	}
+}
+
+func TestHTTPResolverResolveMissingChart(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	mux.HandleFunc("/index.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("entries: {}\n"))
+	})
+
+	hr := NewHTTPResolver(srv.Client(), t.TempDir())
+	if _, err := hr.Resolve(context.Background(), Ref{Repo: srv.URL, Chart: "mychart", Version: "1.0.0"}); err == nil {
+		t.Fatal("expected error resolving chart absent from index")
+	}
+}
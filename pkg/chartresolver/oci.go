@@ -0,0 +1,74 @@
+package chartresolver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/registry"
+)
+
+// OCIResolver resolves charts hosted on an OCI-compliant Helm chart registry
+// (ref.Repo is the registry host + repository path, e.g.
+// "registry.example.com/charts").
+type OCIResolver struct {
+	client  *registry.Client
+	workDir string
+}
+
+// NewOCIResolver constructs an OCIResolver that downloads charts into
+// workDir using client, which should already be authenticated against any
+// private registries it will be asked to pull from (see
+// registry.Client.Login).
+func NewOCIResolver(client *registry.Client, workDir string) *OCIResolver {
+	return &OCIResolver{client: client, workDir: workDir}
+}
+
+// Resolve implements ChartResolver.
+func (or *OCIResolver) Resolve(ctx context.Context, ref Ref) (ResolvedChart, error) {
+	ociRef := fmt.Sprintf("oci://%v/%v:%v", ref.Repo, ref.Chart, ref.Version)
+	result, err := or.client.Pull(ociRef, registry.PullOptWithProv(true))
+	if err != nil {
+		return ResolvedChart{}, errors.Wrapf(err, "error pulling OCI chart: %v", ociRef)
+	}
+
+	if err := os.MkdirAll(or.workDir, 0755); err != nil {
+		return ResolvedChart{}, errors.Wrap(err, "error creating chart work dir")
+	}
+	tgzPath := filepath.Join(or.workDir, fmt.Sprintf("%v-%v.tgz", ref.Chart, ref.Version))
+	if err := os.WriteFile(tgzPath, result.Chart.Data, 0644); err != nil {
+		return ResolvedChart{}, errors.Wrap(err, "error writing pulled chart to disk")
+	}
+
+	chartPath := filepath.Join(or.workDir, fmt.Sprintf("%v-%v", ref.Chart, ref.Version))
+	if err := unpackChart(tgzPath, chartPath); err != nil {
+		return ResolvedChart{}, errors.Wrap(err, "error unpacking pulled chart")
+	}
+
+	return ResolvedChart{Path: chartPath, Digest: result.Manifest.Digest, Ref: ref}, nil
+}
+
+// unpackChart validates and unpacks the packaged chart at tgzPath, writing
+// its contents under destDir.
+func unpackChart(tgzPath, destDir string) error {
+	c, err := loader.Load(tgzPath)
+	if err != nil {
+		return errors.Wrapf(err, "error loading packaged chart: %v", tgzPath)
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return err
+	}
+	for _, f := range c.Raw {
+		p := filepath.Join(destDir, f.Name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, f.Data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
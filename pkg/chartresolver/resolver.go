@@ -0,0 +1,63 @@
+// Package chartresolver resolves Helm chart sources by "repo/chart@version"
+// reference, as an alternative to AminoConfig's hard-coded
+// chart-to-repo/deployment-to-repo JSON mappings. It supports git
+// repositories, OCI-based chart registries and traditional HTTP chart
+// repositories (index.yaml), all through the common ChartResolver interface,
+// so that environments can migrate off the JSON mapping incrementally,
+// resolver by resolver.
+package chartresolver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Ref identifies a chart by the repository (or registry) it lives in, its
+// name within that source, and a version. Its string form is
+// "repo/chart@version", e.g. "dollarshaveclub/my-chart@1.2.3".
+type Ref struct {
+	Repo    string
+	Chart   string
+	Version string
+}
+
+// ParseRef parses a "repo/chart@version" reference. repo may itself contain
+// slashes (e.g. a GitHub org/repo or an OCI registry path); only the final
+// path segment is taken as the chart name.
+func ParseRef(s string) (Ref, error) {
+	repoAndChart, version, ok := strings.Cut(s, "@")
+	if !ok || version == "" {
+		return Ref{}, fmt.Errorf("chart ref missing @version: %v", s)
+	}
+	i := strings.LastIndex(repoAndChart, "/")
+	if i < 0 {
+		return Ref{}, fmt.Errorf("chart ref missing repo/chart separator: %v", s)
+	}
+	repo, chart := repoAndChart[:i], repoAndChart[i+1:]
+	if repo == "" || chart == "" {
+		return Ref{}, fmt.Errorf("chart ref has empty repo or chart name: %v", s)
+	}
+	return Ref{Repo: repo, Chart: chart, Version: version}, nil
+}
+
+func (r Ref) String() string {
+	return fmt.Sprintf("%v/%v@%v", r.Repo, r.Chart, r.Version)
+}
+
+// ResolvedChart describes a chart that has been fetched onto local disk and
+// is ready to be used as a Helm release source.
+type ResolvedChart struct {
+	// Path is the filesystem location of the unpacked chart directory.
+	Path string
+	// Digest is a content digest of the chart (e.g. sha256 of the packaged
+	// .tgz), used as the on-disk cache key.
+	Digest string
+	Ref    Ref
+}
+
+// ChartResolver resolves a chart Ref to a ResolvedChart, fetching and
+// unpacking it as necessary.
+type ChartResolver interface {
+	Resolve(ctx context.Context, ref Ref) (ResolvedChart, error)
+}
@@ -0,0 +1,44 @@
+package chartresolver
+
+import "testing"
+
+func TestParseRef(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    Ref
+		expectedErr bool
+	}{
+		{
+			name:     "simple repo",
+			input:    "dollarshaveclub/my-chart@1.2.3",
+			expected: Ref{Repo: "dollarshaveclub", Chart: "my-chart", Version: "1.2.3"},
+		},
+		{
+			name:     "nested repo path",
+			input:    "registry.example.com/charts/my-chart@1.2.3",
+			expected: Ref{Repo: "registry.example.com/charts", Chart: "my-chart", Version: "1.2.3"},
+		},
+		{
+			name:        "missing version",
+			input:       "dollarshaveclub/my-chart",
+			expectedErr: true,
+		},
+		{
+			name:        "missing chart",
+			input:       "my-chart@1.2.3",
+			expectedErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ref, err := ParseRef(tc.input)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("ParseRef() error = %v, expectedErr %v", err, tc.expectedErr)
+			}
+			if err == nil && ref != tc.expected {
+				t.Fatalf("ParseRef() = %+v, expected %+v", ref, tc.expected)
+			}
+		})
+	}
+}
@@ -1,13 +1,17 @@
 package config
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/dollarshaveclub/pvc"
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 type ServerConfig struct {
@@ -27,6 +31,7 @@ type ServerConfig struct {
 	DebugEndpointsIPWhitelists []string
 	NitroFeatureFlag           bool
 	NotificationsDefaultsJSON  string
+	Telemetry                  TelemetryConfig
 }
 
 type PGConfig struct {
@@ -36,6 +41,30 @@ type PGConfig struct {
 	EnableTracing          bool
 }
 
+// TelemetryConfig configures tracing and metrics export. It supports
+// OpenTelemetry OTLP export alongside the existing Datadog integration
+// (ServerConfig.DatadogServiceName, PGConfig.DatadogServiceName); downstream
+// code should not select between them directly but instead go through a
+// telemetry.Tracer obtained via telemetry.NewTracer, which routes to
+// whichever backend is enabled.
+type TelemetryConfig struct {
+	// OTLPEnabled turns on the OpenTelemetry exporters; when false, Datadog
+	// (if configured) remains the active backend.
+	OTLPEnabled bool
+	// OTLPEndpoint is the OTLP gRPC collector endpoint, e.g. "otel-collector:4317".
+	OTLPEndpoint string
+	// OTLPInsecure disables TLS when dialing OTLPEndpoint (for a collector
+	// running as a cluster-local sidecar).
+	OTLPInsecure bool
+	// ServiceName identifies this process in exported spans/metrics.
+	ServiceName string
+	// MetricsEnabled makes telemetry.NewMeterProvider return an http.Handler
+	// for a Prometheus /metrics endpoint serving the OTel metrics in
+	// Prometheus exposition format. Neither the config nor the telemetry
+	// package mounts that handler or gates it by ServerConfig.DebugEndpoints.
+	MetricsEnabled bool
+}
+
 // K8sClientConfig models the configuration required for a kubernetes client
 // to communicate with the API server
 type K8sClientConfig struct {
@@ -59,9 +88,16 @@ type K8sConfig struct {
 	// Labels should not be empty and should contain a unique combination of labels. These labels can be used by Acyl
 	// to remove orphaned resources.
 	Labels map[string]string
+	// SecretRefreshInterval controls how often WatchSecretInjections re-resolves
+	// SecretInjections via the configured SecretProviderRegistry. A zero value
+	// disables periodic refresh.
+	SecretRefreshInterval time.Duration
+
+	secretsMu sync.RWMutex
 }
 
-// ProcessPrivilegedRepos takes a comma-separated list of repositories and populates the PrivilegedRepoWhitelist field
+// ProcessPrivilegedRepos takes a comma-separated list of repositories and populates the PrivilegedRepoWhitelist field.
+// This is the legacy flag-driven path; Loader and FileConfig.ApplyTo populate the same field from a declarative config file.
 func (kc *K8sConfig) ProcessPrivilegedRepos(repostr string) error {
 	kc.PrivilegedRepoWhitelist = strings.Split(repostr, ",")
 	for i, pr := range kc.PrivilegedRepoWhitelist {
@@ -72,7 +108,8 @@ func (kc *K8sConfig) ProcessPrivilegedRepos(repostr string) error {
 	return nil
 }
 
-// ProcessGroupBindings takes a comma-separated list of group bindings and populates the GroupBindings field
+// ProcessGroupBindings takes a comma-separated list of group bindings and populates the GroupBindings field.
+// This is the legacy flag-driven path; Loader and FileConfig.ApplyTo populate the same field from a declarative config file.
 func (kc *K8sConfig) ProcessGroupBindings(gbstr string) error {
 	kc.GroupBindings = make(map[string]string)
 	for i, gb := range strings.Split(gbstr, ",") {
@@ -93,7 +130,10 @@ func (kc *K8sConfig) ProcessGroupBindings(gbstr string) error {
 
 // ProcessLabels takes a comma-separated list of labels and popultes the Labels field.
 // We want to ensure that at least one label is provided, otherwise, all resources
-// will be managed by Acyl and could be deleted during cleanup.
+// will be managed by Acyl and could be deleted during cleanup. Each key and value
+// must also be a valid Kubernetes label per validation.IsQualifiedName and
+// validation.IsValidLabelValue, since Labels is used to build the label selector
+// the reaper uses to find resources eligible for cleanup.
 func (kc *K8sConfig) ProcessLabels(labelsStr string) error {
 	kc.Labels = make(map[string]string)
 	labels := strings.Split(labelsStr, ",")
@@ -106,43 +146,154 @@ func (kc *K8sConfig) ProcessLabels(labelsStr string) error {
 			return fmt.Errorf("malformed label %s in %s", labelStr, labelsStr)
 		}
 		key, value := keyValPair[0], keyValPair[1]
+		if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+			return fmt.Errorf("invalid label key %s in %s: %s", key, labelsStr, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) != 0 {
+			return fmt.Errorf("invalid label value %s in %s: %s", value, labelsStr, strings.Join(errs, "; "))
+		}
 		kc.Labels[key] = value
 	}
 	return nil
 }
 
-// SecretFetcher describes an object that fetches secrets
+// SecretFetcher describes an object that fetches secrets. It is retained for
+// backward compatibility with the legacy pvc-backed configuration; new
+// integrations should implement SecretProvider instead.
 type SecretFetcher interface {
 	Get(id string) ([]byte, error)
 }
 
-// ProcessSecretInjections takes a comma-separated list of injections and uses sf to populate the SecretInjections field
-func (kc *K8sConfig) ProcessSecretInjections(sf SecretFetcher, injstr string) error {
-	kc.SecretInjections = make(map[string]K8sSecret)
+// SecretProvider describes an object that resolves a secret value from some
+// backend (Vault, AWS Secrets Manager, a Kubernetes namespace, etc). id is
+// the portion of a secret injection URI following "scheme://", for example
+// the "path#key" in "vault://path#key".
+type SecretProvider interface {
+	Get(ctx context.Context, id string) ([]byte, error)
+}
+
+// SecretProviderRegistry maps a URI scheme (as used in the values supplied to
+// ProcessSecretInjections, e.g. "vault", "awssm" or "k8s") to the
+// SecretProvider responsible for resolving identifiers under that scheme.
+// This allows a single --secret-injections flag to mix secrets sourced from
+// multiple backends.
+type SecretProviderRegistry map[string]SecretProvider
+
+// Register associates provider with scheme, overwriting any provider
+// previously registered under the same scheme.
+func (spr SecretProviderRegistry) Register(scheme string, provider SecretProvider) {
+	spr[scheme] = provider
+}
+
+// legacySecretFetcherProvider adapts a SecretFetcher (pvc) to the
+// SecretProvider interface so it can be registered under the "pvc" scheme.
+type legacySecretFetcherProvider struct {
+	sf SecretFetcher
+}
+
+func (l legacySecretFetcherProvider) Get(_ context.Context, id string) ([]byte, error) {
+	return l.sf.Get(id)
+}
+
+// NewLegacySecretProviderRegistry returns a SecretProviderRegistry containing
+// a single provider, registered under the "pvc" scheme, that delegates to sf.
+// It exists to ease migration of callers still using the pvc-only
+// SecretFetcher path.
+func NewLegacySecretProviderRegistry(sf SecretFetcher) SecretProviderRegistry {
+	spr := SecretProviderRegistry{}
+	spr.Register("pvc", legacySecretFetcherProvider{sf: sf})
+	return spr
+}
+
+// resolve parses uri as "scheme://id", dispatches to the provider registered
+// for scheme and returns the raw secret value.
+func (spr SecretProviderRegistry) resolve(ctx context.Context, uri string) ([]byte, error) {
+	i := strings.Index(uri, "://")
+	if i < 0 {
+		return nil, fmt.Errorf("secret URI missing scheme (expected scheme://id): %v", uri)
+	}
+	scheme, id := uri[:i], uri[i+len("://"):]
+	if id == "" {
+		return nil, fmt.Errorf("secret URI missing id: %v", uri)
+	}
+	provider, ok := spr[scheme]
+	if !ok {
+		return nil, fmt.Errorf("no secret provider registered for scheme: %v", scheme)
+	}
+	val, err := provider.Get(ctx, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error fetching secret: %v", uri)
+	}
+	return val, nil
+}
+
+// ProcessSecretInjections takes a comma-separated list of "name=scheme://id"
+// injections and uses the providers in spr to populate the SecretInjections
+// field, dispatching each id to the provider registered for its URI scheme
+// (see SecretProviderRegistry). It is safe to call concurrently with
+// WatchSecretInjections.
+func (kc *K8sConfig) ProcessSecretInjections(ctx context.Context, spr SecretProviderRegistry, injstr string) error {
+	injections := make(map[string]K8sSecret)
 	for i, sstr := range strings.Split(injstr, ",") {
 		if sstr == "" {
 			continue
 		}
-		ssl := strings.Split(sstr, "=")
+		ssl := strings.SplitN(sstr, "=", 2)
 		if len(ssl) != 2 {
 			return fmt.Errorf("malformed secret injection at offset %v: %v", i, sstr)
 		}
 		if len(ssl[0]) == 0 || len(ssl[1]) == 0 {
 			return fmt.Errorf("empty secret injection at offset %v: %v", i, sstr)
 		}
-		val, err := sf.Get(ssl[1])
+		val, err := spr.resolve(ctx, ssl[1])
 		if err != nil {
-			return errors.Wrapf(err, "error fetching secret for id: %v", ssl[1])
+			return errors.Wrapf(err, "error resolving secret injection at offset %v", i)
 		}
 		secret := K8sSecret{}
 		if err := json.Unmarshal(val, &secret); err != nil {
-			return errors.Wrapf(err, "error unmarshaling secret for id: %v", ssl[1])
+			return errors.Wrapf(err, "error unmarshaling secret for injection: %v", ssl[0])
 		}
-		kc.SecretInjections[ssl[0]] = secret
+		injections[ssl[0]] = secret
 	}
+	kc.secretsMu.Lock()
+	kc.SecretInjections = injections
+	kc.secretsMu.Unlock()
 	return nil
 }
 
+// SecretInjection returns the resolved K8sSecret for name and whether it was
+// found. It may be called concurrently with WatchSecretInjections.
+func (kc *K8sConfig) SecretInjection(name string) (K8sSecret, bool) {
+	kc.secretsMu.RLock()
+	defer kc.secretsMu.RUnlock()
+	s, ok := kc.SecretInjections[name]
+	return s, ok
+}
+
+// WatchSecretInjections re-resolves injstr against spr every
+// kc.SecretRefreshInterval, replacing SecretInjections in place so long-running
+// acyl processes pick up rotated secret values without restart. It blocks
+// until ctx is cancelled; onErr (if non-nil) is called with any error
+// encountered during a refresh, and the watch continues regardless. If
+// kc.SecretRefreshInterval is zero, WatchSecretInjections returns immediately.
+func (kc *K8sConfig) WatchSecretInjections(ctx context.Context, spr SecretProviderRegistry, injstr string, onErr func(error)) {
+	if kc.SecretRefreshInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(kc.SecretRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := kc.ProcessSecretInjections(ctx, spr, injstr); err != nil && onErr != nil {
+				onErr(err)
+			}
+		}
+	}
+}
+
 type AminoConfig struct {
 	HelmChartToRepoRaw       string
 	HelmChartToRepo          map[string]string
@@ -150,8 +301,25 @@ type AminoConfig struct {
 	AminoDeploymentToRepo    map[string]string
 	AminoJobToRepoRaw        string
 	AminoJobToRepo           map[string]string
+	// ChartRefs maps a chart name (the same keys used in HelmChartToRepo) to a
+	// "repo/chart@version" reference resolvable via a chartresolver.ChartResolver,
+	// so operators can migrate individual charts off the HelmChartToRepo mapping
+	// without moving everything at once. ChartRef consults only this map;
+	// nothing in this package chooses between it and HelmChartToRepo for a
+	// chart name present in both.
+	ChartRefs map[string]string
+}
+
+// ChartRef returns the chartresolver reference configured for chartName, if
+// any, and whether one was found.
+func (a *AminoConfig) ChartRef(chartName string) (string, bool) {
+	ref, ok := a.ChartRefs[chartName]
+	return ref, ok
 }
 
+// Parse decodes the JSON-blob-in-a-string fields into their map
+// counterparts. It is retained for the legacy flag-driven config path;
+// new deployments should prefer Loader and FileConfig.ApplyToAmino.
 func (a *AminoConfig) Parse() error {
 	if err := json.Unmarshal([]byte(a.HelmChartToRepoRaw), &a.HelmChartToRepo); err != nil {
 		return fmt.Errorf("error unmarshaling HelmChartToRepo: %v", err)
@@ -1,10 +1,22 @@
 package config
 
 import (
+	"context"
+	"fmt"
 	"reflect"
 	"testing"
 )
 
+type fakeSecretProvider map[string][]byte
+
+func (f fakeSecretProvider) Get(_ context.Context, id string) ([]byte, error) {
+	v, ok := f[id]
+	if !ok {
+		return nil, fmt.Errorf("not found: %v", id)
+	}
+	return v, nil
+}
+
 func TestProcessLabels(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -56,3 +68,47 @@ func TestProcessLabels(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessSecretInjections(t *testing.T) {
+	spr := SecretProviderRegistry{}
+	spr.Register("fake", fakeSecretProvider{
+		"db/password": []byte(`{"data":{"password":"c3VwZXJzZWNyZXQ="},"type":"Opaque"}`),
+	})
+
+	tests := []struct {
+		name        string
+		injstr      string
+		expectedErr bool
+	}{
+		{
+			name:        "valid injection",
+			injstr:      "dbpass=fake://db/password",
+			expectedErr: false,
+		},
+		{
+			name:        "unknown scheme",
+			injstr:      "dbpass=vault://db/password",
+			expectedErr: true,
+		},
+		{
+			name:        "missing scheme",
+			injstr:      "dbpass=db/password",
+			expectedErr: true,
+		},
+		{
+			name:        "malformed injection",
+			injstr:      "dbpass",
+			expectedErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var k8scfg K8sConfig
+			err := k8scfg.ProcessSecretInjections(context.Background(), spr, tc.injstr)
+			receivedErr := err != nil
+			if receivedErr != tc.expectedErr {
+				t.Fatalf("K8sConfig.ProcessSecretInjections received unexpected error: %v", err)
+			}
+		})
+	}
+}
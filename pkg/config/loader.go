@@ -0,0 +1,322 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentFileConfigVersion is the only schema version accepted by Loader. It
+// is bumped whenever FileConfig's shape changes in a backwards-incompatible
+// way.
+const CurrentFileConfigVersion = "v1"
+
+// FileConfig is the schema for the declarative config file consumed by
+// Loader. It covers the "safe" subset of configuration that can be
+// hot-reloaded at runtime without restarting acyl: label maps, group
+// bindings, privileged repos and secret injections, plus the Amino
+// chart/deployment/job-to-repo mappings and chart refs (see ApplyToAmino).
+// Everything else (listener addresses, TLS, etc) remains flag/env-var only.
+type FileConfig struct {
+	// Version is the schema version of this file. Must equal
+	// CurrentFileConfigVersion.
+	Version string `yaml:"version" json:"version"`
+	// Include lists additional config files, resolved relative to this
+	// file's directory, whose contents are merged on top of this one (later
+	// includes win on conflicting keys, and win over this file's own values).
+	// This allows a base file plus per-environment overlays.
+	Include []string `yaml:"include,omitempty" json:"include,omitempty"`
+
+	Labels           map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	GroupBindings    map[string]string `yaml:"groupBindings,omitempty" json:"groupBindings,omitempty"`
+	PrivilegedRepos  []string          `yaml:"privilegedRepos,omitempty" json:"privilegedRepos,omitempty"`
+	SecretInjections map[string]string `yaml:"secretInjections,omitempty" json:"secretInjections,omitempty"`
+	Amino            FileAminoConfig   `yaml:"amino,omitempty" json:"amino,omitempty"`
+}
+
+// FileAminoConfig is the structured replacement for AminoConfig's
+// JSON-blob-in-a-string fields.
+type FileAminoConfig struct {
+	HelmChartToRepo       map[string]string `yaml:"helmChartToRepo,omitempty" json:"helmChartToRepo,omitempty"`
+	AminoDeploymentToRepo map[string]string `yaml:"deploymentToRepo,omitempty" json:"deploymentToRepo,omitempty"`
+	AminoJobToRepo        map[string]string `yaml:"jobToRepo,omitempty" json:"jobToRepo,omitempty"`
+	// ChartRefs maps a chart name to a "repo/chart@version" chartresolver
+	// reference; see AminoConfig.ChartRefs.
+	ChartRefs map[string]string `yaml:"chartRefs,omitempty" json:"chartRefs,omitempty"`
+}
+
+// defaults fills in the zero-value defaults for any field the file omitted.
+func (fc *FileConfig) defaults() {
+	if fc.Labels == nil {
+		fc.Labels = map[string]string{}
+	}
+	if fc.GroupBindings == nil {
+		fc.GroupBindings = map[string]string{}
+	}
+	if fc.SecretInjections == nil {
+		fc.SecretInjections = map[string]string{}
+	}
+}
+
+// validate checks fc against the FileConfig schema, independent of any
+// overlays that may still be merged in.
+func (fc *FileConfig) validate() error {
+	if fc.Version != CurrentFileConfigVersion {
+		return fmt.Errorf("unsupported config version %q (expected %q)", fc.Version, CurrentFileConfigVersion)
+	}
+	for k, v := range fc.GroupBindings {
+		if k == "" || v == "" {
+			return fmt.Errorf("group binding keys and values must be non-empty")
+		}
+	}
+	for i, r := range fc.PrivilegedRepos {
+		if sl := strings.Split(r, "/"); len(sl) != 2 {
+			return fmt.Errorf("malformed privileged repo at offset %v: %v", i, r)
+		}
+	}
+	return nil
+}
+
+// merge overlays other on top of fc: scalar maps/slices in other take
+// precedence key-by-key (maps) or wholesale (slices, if non-empty).
+func (fc *FileConfig) merge(other FileConfig) {
+	for k, v := range other.Labels {
+		fc.Labels[k] = v
+	}
+	for k, v := range other.GroupBindings {
+		fc.GroupBindings[k] = v
+	}
+	for k, v := range other.SecretInjections {
+		fc.SecretInjections[k] = v
+	}
+	if len(other.PrivilegedRepos) > 0 {
+		fc.PrivilegedRepos = other.PrivilegedRepos
+	}
+	for k, v := range other.Amino.HelmChartToRepo {
+		if fc.Amino.HelmChartToRepo == nil {
+			fc.Amino.HelmChartToRepo = map[string]string{}
+		}
+		fc.Amino.HelmChartToRepo[k] = v
+	}
+	for k, v := range other.Amino.AminoDeploymentToRepo {
+		if fc.Amino.AminoDeploymentToRepo == nil {
+			fc.Amino.AminoDeploymentToRepo = map[string]string{}
+		}
+		fc.Amino.AminoDeploymentToRepo[k] = v
+	}
+	for k, v := range other.Amino.AminoJobToRepo {
+		if fc.Amino.AminoJobToRepo == nil {
+			fc.Amino.AminoJobToRepo = map[string]string{}
+		}
+		fc.Amino.AminoJobToRepo[k] = v
+	}
+	for k, v := range other.Amino.ChartRefs {
+		if fc.Amino.ChartRefs == nil {
+			fc.Amino.ChartRefs = map[string]string{}
+		}
+		fc.Amino.ChartRefs[k] = v
+	}
+}
+
+// ChangeEvent describes a successful (re)load of the declarative config file,
+// or an error encountered while attempting one. Consumers (k8s controllers,
+// notification defaults, the reaper) should ignore events with a non-nil Err
+// and keep operating on the last good Config.
+type ChangeEvent struct {
+	Config FileConfig
+	Err    error
+}
+
+// Loader reads a versioned, includable FileConfig from disk and, once
+// Watch is called, emits a ChangeEvent on Events() whenever the file (or any
+// of its includes) changes, so that long-running acyl processes can hot
+// reload label maps, group bindings, privileged repos and secret injections
+// (and the Amino mappings in FileConfig.Amino) without a restart. All other
+// configuration continues to flow through the
+// existing env-var/flag path (see K8sConfig.Process*).
+type Loader struct {
+	path string
+
+	mu      sync.RWMutex
+	current FileConfig
+	// paths holds the absolute path of the root config file plus every
+	// include reachable from it (at any depth), as of the last successful
+	// load. It is what Watch uses to decide which directories to watch.
+	paths []string
+
+	watcher *fsnotify.Watcher
+	events  chan ChangeEvent
+}
+
+// NewLoader constructs a Loader for the config file at path and performs an
+// initial load, returning an error if the file (or any include) is missing,
+// malformed or fails schema validation.
+func NewLoader(path string) (*Loader, error) {
+	l := &Loader{path: path, events: make(chan ChangeEvent, 1)}
+	fc, paths, err := l.load()
+	if err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	l.current = fc
+	l.paths = paths
+	l.mu.Unlock()
+	return l, nil
+}
+
+// load reads and merges path and its includes (at any depth) into a single
+// validated FileConfig, without mutating Loader state. It also returns the
+// absolute path of every file visited, for use by watchedPaths.
+func (l *Loader) load() (FileConfig, []string, error) {
+	seen := map[string]bool{}
+	var paths []string
+	fc, err := l.loadFile(l.path, seen, &paths)
+	return fc, paths, err
+}
+
+func (l *Loader) loadFile(path string, seen map[string]bool, paths *[]string) (FileConfig, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return FileConfig{}, errors.Wrapf(err, "error resolving path: %v", path)
+	}
+	if seen[abs] {
+		return FileConfig{}, fmt.Errorf("circular include detected at: %v", path)
+	}
+	seen[abs] = true
+	*paths = append(*paths, abs)
+
+	b, err := os.ReadFile(abs)
+	if err != nil {
+		return FileConfig{}, errors.Wrapf(err, "error reading config file: %v", abs)
+	}
+	var fc FileConfig
+	if err := yaml.Unmarshal(b, &fc); err != nil {
+		return FileConfig{}, errors.Wrapf(err, "error parsing config file: %v", abs)
+	}
+	fc.defaults()
+	if err := fc.validate(); err != nil {
+		return FileConfig{}, errors.Wrapf(err, "invalid config file: %v", abs)
+	}
+
+	dir := filepath.Dir(abs)
+	for _, inc := range fc.Include {
+		incPath := inc
+		if !filepath.IsAbs(incPath) {
+			incPath = filepath.Join(dir, incPath)
+		}
+		overlay, err := l.loadFile(incPath, seen, paths)
+		if err != nil {
+			return FileConfig{}, err
+		}
+		fc.merge(overlay)
+	}
+	return fc, nil
+}
+
+// Current returns the most recently loaded (and validated) FileConfig.
+func (l *Loader) Current() FileConfig {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.current
+}
+
+// Events returns the channel on which ChangeEvents are published once Watch
+// has been called.
+func (l *Loader) Events() <-chan ChangeEvent {
+	return l.events
+}
+
+// Watch begins watching the config file (and its includes, at the time of
+// the last successful load) for changes using fsnotify, reloading and
+// publishing a ChangeEvent on Events() for each change. Watch returns once
+// the watcher is established; it runs the notification loop in a background
+// goroutine until Close is called.
+func (l *Loader) Watch() error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "error creating fsnotify watcher")
+	}
+	for _, p := range l.watchedPaths() {
+		if err := w.Add(filepath.Dir(p)); err != nil {
+			w.Close()
+			return errors.Wrapf(err, "error watching directory of: %v", p)
+		}
+	}
+	l.watcher = w
+	go l.watchLoop()
+	return nil
+}
+
+// watchedPaths returns the absolute path of the root config file and every
+// include reachable from it, at any depth, as of the last successful load.
+func (l *Loader) watchedPaths() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.paths
+}
+
+func (l *Loader) watchLoop() {
+	for {
+		select {
+		case ev, ok := <-l.watcher.Events:
+			if !ok {
+				return
+			}
+			if ev.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			fc, paths, err := l.load()
+			if err != nil {
+				l.events <- ChangeEvent{Err: err}
+				continue
+			}
+			l.mu.Lock()
+			l.current = fc
+			l.paths = paths
+			l.mu.Unlock()
+			l.events <- ChangeEvent{Config: fc}
+		case err, ok := <-l.watcher.Errors:
+			if !ok {
+				return
+			}
+			l.events <- ChangeEvent{Err: err}
+		}
+	}
+}
+
+// Close stops the file watcher, if running, and closes Events().
+func (l *Loader) Close() error {
+	var err error
+	if l.watcher != nil {
+		err = l.watcher.Close()
+	}
+	close(l.events)
+	return err
+}
+
+// ApplyTo populates the safe-to-hot-reload fields of kc (Labels,
+// GroupBindings, PrivilegedRepoWhitelist) from fc, in place of the
+// comma-separated-string ProcessX methods. SecretInjections is intentionally
+// excluded since resolving it requires a SecretProviderRegistry; callers
+// should pass fc.SecretInjections (joined per ProcessSecretInjections' format)
+// through K8sConfig.ProcessSecretInjections separately.
+func (fc FileConfig) ApplyTo(kc *K8sConfig) {
+	kc.Labels = fc.Labels
+	kc.GroupBindings = fc.GroupBindings
+	kc.PrivilegedRepoWhitelist = fc.PrivilegedRepos
+}
+
+// ApplyToAmino populates ac's maps from fc.Amino, replacing the
+// JSON-blob-in-a-string AminoConfig.Parse path.
+func (fc FileConfig) ApplyToAmino(ac *AminoConfig) {
+	ac.HelmChartToRepo = fc.Amino.HelmChartToRepo
+	ac.AminoDeploymentToRepo = fc.Amino.AminoDeploymentToRepo
+	ac.AminoJobToRepo = fc.Amino.AminoJobToRepo
+	ac.ChartRefs = fc.Amino.ChartRefs
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTempFile(t *testing.T, dir, name, contents string) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, []byte(contents), 0644); err != nil {
+		t.Fatalf("error writing temp file: %v", err)
+	}
+	return p
+}
+
+func TestLoaderLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "overlay.yaml", `
+version: v1
+labels:
+  env: prod
+groupBindings:
+  admins: cluster-admin
+`)
+	base := writeTempFile(t, dir, "base.yaml", `
+version: v1
+include:
+  - overlay.yaml
+labels:
+  acyl.dev/managed-by: nitro
+privilegedRepos:
+  - foo/bar
+`)
+
+	l, err := NewLoader(base)
+	if err != nil {
+		t.Fatalf("NewLoader returned unexpected error: %v", err)
+	}
+	cur := l.Current()
+
+	expectedLabels := map[string]string{
+		"acyl.dev/managed-by": "nitro",
+		"env":                 "prod",
+	}
+	if !reflect.DeepEqual(cur.Labels, expectedLabels) {
+		t.Fatalf("unexpected labels after merge: %v", cur.Labels)
+	}
+	expectedBindings := map[string]string{"admins": "cluster-admin"}
+	if !reflect.DeepEqual(cur.GroupBindings, expectedBindings) {
+		t.Fatalf("unexpected group bindings after merge: %v", cur.GroupBindings)
+	}
+	if !reflect.DeepEqual(cur.PrivilegedRepos, []string{"foo/bar"}) {
+		t.Fatalf("unexpected privileged repos: %v", cur.PrivilegedRepos)
+	}
+}
+
+func TestLoaderWatchedPathsNestedIncludes(t *testing.T) {
+	dir := t.TempDir()
+	leaf := writeTempFile(t, dir, "leaf.yaml", `
+version: v1
+labels:
+  env: prod
+`)
+	overlay := writeTempFile(t, dir, "overlay.yaml", `
+version: v1
+include:
+  - leaf.yaml
+groupBindings:
+  admins: cluster-admin
+`)
+	base := writeTempFile(t, dir, "base.yaml", `
+version: v1
+include:
+  - overlay.yaml
+labels:
+  acyl.dev/managed-by: nitro
+`)
+
+	l, err := NewLoader(base)
+	if err != nil {
+		t.Fatalf("NewLoader returned unexpected error: %v", err)
+	}
+
+	absBase, _ := filepath.Abs(base)
+	absOverlay, _ := filepath.Abs(overlay)
+	absLeaf, _ := filepath.Abs(leaf)
+	expected := map[string]bool{absBase: true, absOverlay: true, absLeaf: true}
+
+	got := l.watchedPaths()
+	if len(got) != len(expected) {
+		t.Fatalf("watchedPaths() = %v, expected one entry per file in the include tree: %v", got, expected)
+	}
+	for _, p := range got {
+		if !expected[p] {
+			t.Fatalf("watchedPaths() returned unexpected path: %v", p)
+		}
+	}
+	if !expected[absLeaf] {
+		t.Fatal("expected second-level include leaf.yaml to be watched")
+	}
+}
+
+func TestLoaderLoadErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{
+			name:     "missing version",
+			contents: `labels: {foo: bar}`,
+		},
+		{
+			name:     "bad version",
+			contents: `version: v99`,
+		},
+		{
+			name: "malformed yaml",
+			contents: `version: v1
+labels: [this, is, a, list, not, a, map]`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			p := writeTempFile(t, dir, "acyl.yaml", tc.contents)
+			if _, err := NewLoader(p); err == nil {
+				t.Fatal("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestFileConfigApplyTo(t *testing.T) {
+	fc := FileConfig{
+		Labels:          map[string]string{"a": "b"},
+		GroupBindings:   map[string]string{"g": "cluster-admin"},
+		PrivilegedRepos: []string{"foo/bar"},
+	}
+	var kc K8sConfig
+	fc.ApplyTo(&kc)
+	if !reflect.DeepEqual(kc.Labels, fc.Labels) {
+		t.Fatalf("labels not applied: %v", kc.Labels)
+	}
+	if !reflect.DeepEqual(kc.GroupBindings, fc.GroupBindings) {
+		t.Fatalf("group bindings not applied: %v", kc.GroupBindings)
+	}
+	if !reflect.DeepEqual(kc.PrivilegedRepoWhitelist, fc.PrivilegedRepos) {
+		t.Fatalf("privileged repos not applied: %v", kc.PrivilegedRepoWhitelist)
+	}
+}
@@ -0,0 +1,53 @@
+package reaper
+
+import (
+	"net"
+	"net/http"
+)
+
+// Handler returns an http.Handler that triggers an on-demand Sweep when
+// invoked, restricted to clients whose address matches one of the CIDRs in
+// ipWhitelists (see config.ServerConfig.DebugEndpointsIPWhitelists). It is
+// meant to be mounted alongside acyl's other debug endpoints, which share the
+// same gating.
+func (r *Reaper) Handler(ipWhitelists []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !remoteAddrAllowed(req.RemoteAddr, ipWhitelists) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		if req.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := r.Sweep(req.Context()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func remoteAddrAllowed(remoteAddr string, ipWhitelists []string) bool {
+	if len(ipWhitelists) == 0 {
+		return false
+	}
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range ipWhitelists {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package reaper
+
+import "testing"
+
+func TestRemoteAddrAllowed(t *testing.T) {
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		ipWhitelists []string
+		expected     bool
+	}{
+		{
+			name:         "allowed with port",
+			remoteAddr:   "10.0.0.5:54321",
+			ipWhitelists: []string{"10.0.0.0/8"},
+			expected:     true,
+		},
+		{
+			name:         "allowed without port",
+			remoteAddr:   "10.0.0.5",
+			ipWhitelists: []string{"10.0.0.0/8"},
+			expected:     true,
+		},
+		{
+			name:         "not in any whitelist",
+			remoteAddr:   "192.168.1.5:1234",
+			ipWhitelists: []string{"10.0.0.0/8"},
+			expected:     false,
+		},
+		{
+			name:         "no whitelists configured",
+			remoteAddr:   "10.0.0.5:54321",
+			ipWhitelists: nil,
+			expected:     false,
+		},
+		{
+			name:         "malformed remote addr",
+			remoteAddr:   "not-an-ip",
+			ipWhitelists: []string{"10.0.0.0/8"},
+			expected:     false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := remoteAddrAllowed(tc.remoteAddr, tc.ipWhitelists); got != tc.expected {
+				t.Fatalf("remoteAddrAllowed() = %v, expected %v", got, tc.expected)
+			}
+		})
+	}
+}
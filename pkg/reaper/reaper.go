@@ -0,0 +1,329 @@
+// Package reaper finds and deletes Kubernetes resources that Acyl created
+// (identified via K8sConfig.Labels) but which no longer belong to any
+// environment known to Acyl.
+package reaper
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dollarshaveclub/acyl/pkg/config"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// EnvironmentNameLabel is the label key the reaper reads off each resource to
+// determine which Acyl environment owns it.
+const EnvironmentNameLabel = "acyl.dev/environment-name"
+
+// EnvironmentStore answers whether an Acyl environment still exists. It is
+// satisfied by the environment DB layer.
+type EnvironmentStore interface {
+	EnvironmentExists(ctx context.Context, name string) (bool, error)
+}
+
+// Reaper periodically (or on demand) lists cluster resources matching its
+// selector (see UpdateSelector) and deletes any whose EnvironmentNameLabel no
+// longer corresponds to a known environment.
+type Reaper struct {
+	client    kubernetes.Interface
+	discovery discovery.DiscoveryInterface
+	dynamic   dynamic.Interface
+	store     EnvironmentStore
+	interval  time.Duration
+
+	mu       sync.RWMutex
+	selector labels.Selector
+}
+
+// New constructs a Reaper that uses selectorLabels (typically
+// config.K8sConfig.Labels) to find Acyl-managed resources, store to
+// determine whether the owning environment still exists, and interval as the
+// period for Run's sweep loop. The selector can later be changed via
+// UpdateSelector, e.g. in response to config.Loader hot-reload events.
+func New(client kubernetes.Interface, disco discovery.DiscoveryInterface, dyn dynamic.Interface, store EnvironmentStore, selectorLabels map[string]string, interval time.Duration) (*Reaper, error) {
+	r := &Reaper{
+		client:    client,
+		discovery: disco,
+		dynamic:   dyn,
+		store:     store,
+		interval:  interval,
+	}
+	if err := r.UpdateSelector(selectorLabels); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// NewFromConfig is a convenience wrapper around New that takes the relevant
+// fields directly from config.K8sConfig and config.ServerConfig.
+func NewFromConfig(client kubernetes.Interface, disco discovery.DiscoveryInterface, dyn dynamic.Interface, store EnvironmentStore, kc *config.K8sConfig, sc config.ServerConfig) (*Reaper, error) {
+	return New(client, disco, dyn, store, kc.Labels, time.Duration(sc.ReaperIntervalSecs)*time.Second)
+}
+
+// UpdateSelector replaces the label selector used by Sweep with one built
+// from selectorLabels. It refuses an empty selector, since that would match
+// (and make eligible for deletion) every resource in the cluster.
+func (r *Reaper) UpdateSelector(selectorLabels map[string]string) error {
+	if len(selectorLabels) == 0 {
+		return fmt.Errorf("at least one selector label is required, refusing to manage all cluster resources")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.selector = labels.SelectorFromSet(selectorLabels)
+	return nil
+}
+
+// currentSelector returns the selector in effect for the next Sweep.
+func (r *Reaper) currentSelector() labels.Selector {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.selector
+}
+
+// WatchLoader applies each ChangeEvent from events to the reaper's selector
+// via UpdateSelector, so that edits to K8sConfig.Labels in a config.Loader's
+// declarative config file take effect without restarting acyl. It blocks
+// until ctx is cancelled or events is closed. errf, if non-nil, is called
+// with any error from a malformed event or an invalid (empty) label set; the
+// watch continues regardless using the last valid selector.
+func (r *Reaper) WatchLoader(ctx context.Context, events <-chan config.ChangeEvent, errf func(error)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			if ev.Err != nil {
+				if errf != nil {
+					errf(ev.Err)
+				}
+				continue
+			}
+			if err := r.UpdateSelector(ev.Config.Labels); err != nil && errf != nil {
+				errf(err)
+			}
+		}
+	}
+}
+
+// Run executes Sweep every r.interval until ctx is cancelled. errf, if
+// non-nil, is called with the error from any failed sweep; Run otherwise
+// continues regardless of individual sweep failures.
+func (r *Reaper) Run(ctx context.Context, errf func(error)) {
+	if r.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Sweep(ctx); err != nil && errf != nil {
+				errf(err)
+			}
+		}
+	}
+}
+
+// Sweep lists every resource kind the reaper knows how to reap that matches
+// r.selector, and deletes any whose EnvironmentNameLabel refers to an
+// environment that no longer exists according to r.store. A failure to
+// check or delete one resource does not stop the sweep of the rest: Sweep
+// attempts every kind and every matching item regardless, and returns all
+// errors encountered, except list failures for discovered kinds, which
+// sweepDiscovered skips.
+func (r *Reaper) Sweep(ctx context.Context) error {
+	var errs []error
+
+	errs = append(errs, r.sweepBuiltins(ctx)...)
+	errs = append(errs, r.sweepDiscovered(ctx)...)
+
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return fmt.Errorf("errors during reaper sweep: %v", msgs)
+}
+
+func (r *Reaper) sweepBuiltins(ctx context.Context) []error {
+	opts := metav1.ListOptions{LabelSelector: r.currentSelector().String()}
+
+	var errs []error
+	nsList, err := r.client.CoreV1().Namespaces().List(ctx, opts)
+	if err != nil {
+		errs = append(errs, errors.Wrap(err, "error listing namespaces"))
+	} else {
+		for _, ns := range nsList.Items {
+			if err := r.reapIfOrphan(ctx, ns.Labels, func() error {
+				return r.client.CoreV1().Namespaces().Delete(ctx, ns.Name, metav1.DeleteOptions{})
+			}); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return append(errs, r.sweepNamespacedBuiltins(ctx, opts)...)
+}
+
+func (r *Reaper) sweepNamespacedBuiltins(ctx context.Context, opts metav1.ListOptions) []error {
+	var errs []error
+
+	deps, err := r.client.AppsV1().Deployments(corev1.NamespaceAll).List(ctx, opts)
+	if err != nil {
+		errs = append(errs, errors.Wrap(err, "error listing deployments"))
+	}
+	for _, d := range deps.Items {
+		if err := r.reapIfOrphan(ctx, d.Labels, func() error {
+			return r.client.AppsV1().Deployments(d.Namespace).Delete(ctx, d.Name, metav1.DeleteOptions{})
+		}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	svcs, err := r.client.CoreV1().Services(corev1.NamespaceAll).List(ctx, opts)
+	if err != nil {
+		errs = append(errs, errors.Wrap(err, "error listing services"))
+	}
+	for _, s := range svcs.Items {
+		if err := r.reapIfOrphan(ctx, s.Labels, func() error {
+			return r.client.CoreV1().Services(s.Namespace).Delete(ctx, s.Name, metav1.DeleteOptions{})
+		}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	cms, err := r.client.CoreV1().ConfigMaps(corev1.NamespaceAll).List(ctx, opts)
+	if err != nil {
+		errs = append(errs, errors.Wrap(err, "error listing configmaps"))
+	}
+	for _, cm := range cms.Items {
+		if err := r.reapIfOrphan(ctx, cm.Labels, func() error {
+			return r.client.CoreV1().ConfigMaps(cm.Namespace).Delete(ctx, cm.Name, metav1.DeleteOptions{})
+		}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	secrets, err := r.client.CoreV1().Secrets(corev1.NamespaceAll).List(ctx, opts)
+	if err != nil {
+		errs = append(errs, errors.Wrap(err, "error listing secrets"))
+	}
+	for _, s := range secrets.Items {
+		if err := r.reapIfOrphan(ctx, s.Labels, func() error {
+			return r.client.CoreV1().Secrets(s.Namespace).Delete(ctx, s.Name, metav1.DeleteOptions{})
+		}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// sweepDiscovered reaps resources of any additional namespaced GVR exposed
+// by the cluster's discovery API that supports list and delete, beyond the
+// built-in kinds handled above. This lets the reaper clean up CRDs
+// (VirtualServices, Helm release secrets in non-standard shapes, etc)
+// without Acyl needing to know about them ahead of time.
+func (r *Reaper) sweepDiscovered(ctx context.Context) []error {
+	if r.discovery == nil || r.dynamic == nil {
+		return nil
+	}
+	_, apiResourceLists, err := r.discovery.ServerGroupsAndResources()
+	if err != nil {
+		return []error{errors.Wrap(err, "error discovering server resources")}
+	}
+	var errs []error
+	opts := metav1.ListOptions{LabelSelector: r.currentSelector().String()}
+	for _, rl := range apiResourceLists {
+		gv, err := schema.ParseGroupVersion(rl.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, res := range rl.APIResources {
+			if !res.Namespaced || !containsVerb(res.Verbs, "list") || !containsVerb(res.Verbs, "delete") {
+				continue
+			}
+			if isBuiltinGVR(gv.WithResource(res.Name)) {
+				continue
+			}
+			gvr := gv.WithResource(res.Name)
+			list, err := r.dynamic.Resource(gvr).Namespace(corev1.NamespaceAll).List(ctx, opts)
+			if err != nil {
+				continue // best-effort: some discovered resources reject list with a selector
+			}
+			for _, item := range list.Items {
+				if err := r.reapIfOrphan(ctx, item.GetLabels(), func() error {
+					return r.dynamic.Resource(gvr).Namespace(item.GetNamespace()).Delete(ctx, item.GetName(), metav1.DeleteOptions{})
+				}); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+	return errs
+}
+
+func isBuiltinGVR(gvr schema.GroupVersionResource) bool {
+	switch gvr {
+	case schema.GroupVersionResource{Version: "v1", Resource: "namespaces"},
+		schema.GroupVersionResource{Version: "v1", Resource: "services"},
+		schema.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+		schema.GroupVersionResource{Version: "v1", Resource: "secrets"},
+		schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}:
+		return true
+	}
+	return false
+}
+
+func containsVerb(verbs metav1.Verbs, verb string) bool {
+	for _, v := range verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Reaper) isOrphan(ctx context.Context, resourceLabels map[string]string) (bool, error) {
+	envName, ok := resourceLabels[EnvironmentNameLabel]
+	if !ok {
+		// Matched the selector but carries no environment label: not ours to
+		// reap, since we can't tell whether it's orphaned.
+		return false, nil
+	}
+	exists, err := r.store.EnvironmentExists(ctx, envName)
+	if err != nil {
+		return false, errors.Wrapf(err, "error checking existence of environment: %v", envName)
+	}
+	return !exists, nil
+}
+
+func (r *Reaper) reapIfOrphan(ctx context.Context, resourceLabels map[string]string, del func() error) error {
+	orphan, err := r.isOrphan(ctx, resourceLabels)
+	if err != nil {
+		return err
+	}
+	if !orphan {
+		return nil
+	}
+	if err := del(); err != nil {
+		return errors.Wrap(err, "error deleting orphaned resource")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package reaper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dollarshaveclub/acyl/pkg/config"
+)
+
+func TestUpdateSelectorRejectsEmpty(t *testing.T) {
+	r, err := New(nil, nil, nil, nil, map[string]string{"acyl.dev/managed-by": "nitro"}, 0)
+	if err != nil {
+		t.Fatalf("New returned unexpected error: %v", err)
+	}
+	if err := r.UpdateSelector(nil); err == nil {
+		t.Fatal("expected error updating selector to empty label set")
+	}
+	if got := r.currentSelector().String(); got != "acyl.dev/managed-by=nitro" {
+		t.Fatalf("selector should be unchanged after rejected update, got: %v", got)
+	}
+}
+
+func TestWatchLoaderUpdatesSelector(t *testing.T) {
+	r, err := New(nil, nil, nil, nil, map[string]string{"acyl.dev/managed-by": "nitro"}, 0)
+	if err != nil {
+		t.Fatalf("New returned unexpected error: %v", err)
+	}
+
+	events := make(chan config.ChangeEvent, 2)
+	gotErr := make(chan error, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		r.WatchLoader(ctx, events, func(err error) { gotErr <- err })
+		close(done)
+	}()
+
+	events <- config.ChangeEvent{Config: config.FileConfig{Labels: map[string]string{"env": "prod"}}}
+	events <- config.ChangeEvent{Err: nil, Config: config.FileConfig{Labels: nil}}
+
+	// Wait for the watch goroutine to process both events: events are handled
+	// in order, so once the empty-labels event has been reported through errf
+	// the first event has already been applied.
+	select {
+	case <-gotErr:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected an error from the empty-labels event")
+	}
+
+	cancel()
+	<-done
+
+	if got := r.currentSelector().String(); got != "env=prod" {
+		t.Fatalf("selector was never updated, got: %v", got)
+	}
+}
+
+func TestNewFromConfigTakesPointer(t *testing.T) {
+	kc := &config.K8sConfig{}
+	if err := kc.ProcessLabels("acyl.dev/managed-by=nitro"); err != nil {
+		t.Fatalf("ProcessLabels returned unexpected error: %v", err)
+	}
+	r, err := NewFromConfig(nil, nil, nil, nil, kc, config.ServerConfig{ReaperIntervalSecs: 60})
+	if err != nil {
+		t.Fatalf("NewFromConfig returned unexpected error: %v", err)
+	}
+	if got := r.currentSelector().String(); got != "acyl.dev/managed-by=nitro" {
+		t.Fatalf("unexpected selector: %v", got)
+	}
+}
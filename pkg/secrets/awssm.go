@@ -0,0 +1,70 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/dollarshaveclub/acyl/pkg/config"
+	"github.com/pkg/errors"
+)
+
+func credentialsFromConfig(creds config.AWSCreds) *credentials.Credentials {
+	return credentials.NewStaticCredentials(creds.AccessKeyID, creds.SecretAccessKey, "")
+}
+
+// AWSSMProvider is a config.SecretProvider that resolves secrets from AWS
+// Secrets Manager. ids are a secret ARN or name, optionally followed by
+// "#key" to select a single field out of a JSON-encoded secret value.
+type AWSSMProvider struct {
+	client *secretsmanager.SecretsManager
+}
+
+// NewAWSSMProvider constructs an AWSSMProvider using cfg for region/retry
+// behavior and creds for static credentials (if set; otherwise the default
+// AWS credential chain is used).
+func NewAWSSMProvider(cfg config.AWSConfig, creds config.AWSCreds) (*AWSSMProvider, error) {
+	awscfg := aws.NewConfig().WithRegion(cfg.Region).WithMaxRetries(int(cfg.MaxRetries))
+	if creds.AccessKeyID != "" {
+		awscfg = awscfg.WithCredentials(credentialsFromConfig(creds))
+	}
+	sess, err := session.NewSession(awscfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating aws session")
+	}
+	return &AWSSMProvider{client: secretsmanager.New(sess)}, nil
+}
+
+// Get implements config.SecretProvider. If id contains "#key", the secret
+// value is parsed as JSON and the named field is returned; otherwise the raw
+// secret value (string or binary) is returned as-is.
+func (ap *AWSSMProvider) Get(ctx context.Context, id string) ([]byte, error) {
+	arn, key, hasKey := strings.Cut(id, "#")
+	out, err := ap.client.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(arn),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting secret value for %v", arn)
+	}
+	val := out.SecretBinary
+	if out.SecretString != nil {
+		val = []byte(*out.SecretString)
+	}
+	if !hasKey {
+		return val, nil
+	}
+	fields := map[string]string{}
+	if err := json.Unmarshal(val, &fields); err != nil {
+		return nil, errors.Wrapf(err, "error unmarshaling secret %v as JSON to extract key %v", arn, key)
+	}
+	v, ok := fields[key]
+	if !ok {
+		return nil, fmt.Errorf("key %v not found in secret %v", key, arn)
+	}
+	return []byte(v), nil
+}
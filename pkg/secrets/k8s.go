@@ -0,0 +1,50 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// K8sProvider is a config.SecretProvider that resolves secrets from
+// Kubernetes Secret objects in a configurable namespace. ids are of the form
+// "name#key", where name is the Secret's name and key is the entry within
+// its Data map.
+type K8sProvider struct {
+	client    kubernetes.Interface
+	namespace string
+}
+
+// NewK8sProvider constructs a K8sProvider that reads Secrets from namespace
+// using client.
+func NewK8sProvider(client kubernetes.Interface, namespace string) *K8sProvider {
+	return &K8sProvider{client: client, namespace: namespace}
+}
+
+// Get implements config.SecretProvider.
+func (kp *K8sProvider) Get(ctx context.Context, id string) ([]byte, error) {
+	name, key, ok := strings.Cut(id, "#")
+	if !ok || name == "" || key == "" {
+		return nil, fmt.Errorf("malformed k8s secret id (expected name#key): %v", id)
+	}
+	secret, err := kp.client.CoreV1().Secrets(kp.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting secret %v/%v", kp.namespace, name)
+	}
+	return dataValue(secret, key)
+}
+
+func dataValue(secret *corev1.Secret, key string) ([]byte, error) {
+	if v, ok := secret.Data[key]; ok {
+		return v, nil
+	}
+	if v, ok := secret.StringData[key]; ok {
+		return []byte(v), nil
+	}
+	return nil, fmt.Errorf("key %v not found in secret %v/%v", key, secret.Namespace, secret.Name)
+}
@@ -0,0 +1,148 @@
+// Package secrets provides config.SecretProvider implementations backed by
+// Vault, AWS Secrets Manager and Kubernetes Secrets.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dollarshaveclub/acyl/pkg/config"
+	vaultapi "github.com/hashicorp/vault/api"
+	"github.com/pkg/errors"
+)
+
+// defaultTokenRenewMargin is how far before a Vault token's TTL expires that
+// VaultProvider attempts to renew or re-login.
+const defaultTokenRenewMargin = 30 * time.Second
+
+// VaultProvider is a config.SecretProvider that resolves secrets from Vault.
+// ids are of the form "path#key", where path is the Vault secret path and key
+// selects a single field within that secret (e.g. "secret/data/acyl/foo#password").
+// When cfg.K8sAuth is set, VaultProvider logs in using the Kubernetes auth
+// method and transparently renews the resulting token before it expires.
+type VaultProvider struct {
+	cfg    config.VaultConfig
+	client *vaultapi.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewVaultProvider constructs a VaultProvider and, if cfg.K8sAuth is set,
+// performs an initial Kubernetes auth login.
+func NewVaultProvider(ctx context.Context, cfg config.VaultConfig) (*VaultProvider, error) {
+	vc := vaultapi.DefaultConfig()
+	vc.Address = cfg.Addr
+	client, err := vaultapi.NewClient(vc)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating vault client")
+	}
+	vp := &VaultProvider{cfg: cfg, client: client}
+	switch {
+	case cfg.K8sAuth:
+		if err := vp.k8sLogin(ctx); err != nil {
+			return nil, errors.Wrap(err, "error performing initial vault k8s auth login")
+		}
+	case cfg.TokenAuth:
+		vp.setToken(cfg.Token, time.Time{})
+	default:
+		return nil, fmt.Errorf("vault config must specify either K8sAuth or TokenAuth")
+	}
+	return vp, nil
+}
+
+func (vp *VaultProvider) setToken(token string, expiresAt time.Time) {
+	vp.mu.Lock()
+	defer vp.mu.Unlock()
+	vp.token = token
+	vp.expiresAt = expiresAt
+	vp.client.SetToken(token)
+}
+
+// k8sLogin authenticates to Vault using the Kubernetes auth method, reading
+// the service account JWT from cfg.K8sJWTPath and authenticating against
+// cfg.K8sAuthPath ("kubernetes" if unset) with role cfg.K8sRole.
+func (vp *VaultProvider) k8sLogin(ctx context.Context) error {
+	jwt, err := os.ReadFile(vp.cfg.K8sJWTPath)
+	if err != nil {
+		return errors.Wrapf(err, "error reading k8s service account JWT at %v", vp.cfg.K8sJWTPath)
+	}
+	authPath := vp.cfg.K8sAuthPath
+	if authPath == "" {
+		authPath = "kubernetes"
+	}
+	secret, err := vp.client.Logical().WriteWithContext(ctx, fmt.Sprintf("auth/%v/login", authPath), map[string]interface{}{
+		"jwt":  string(jwt),
+		"role": vp.cfg.K8sRole,
+	})
+	if err != nil {
+		return errors.Wrap(err, "error logging in to vault via k8s auth")
+	}
+	if secret == nil || secret.Auth == nil {
+		return fmt.Errorf("vault k8s auth login returned no auth info")
+	}
+	ttl := time.Duration(secret.Auth.LeaseDuration) * time.Second
+	vp.setToken(secret.Auth.ClientToken, time.Now().Add(ttl))
+	return nil
+}
+
+// ensureValidToken renews (or, failing that, re-logs in) the current Vault
+// token if it is within defaultTokenRenewMargin of expiring. It is a no-op
+// for token-auth configurations, which have no TTL to track.
+func (vp *VaultProvider) ensureValidToken(ctx context.Context) error {
+	if !vp.cfg.K8sAuth {
+		return nil
+	}
+	vp.mu.Lock()
+	expiresAt := vp.expiresAt
+	vp.mu.Unlock()
+	if time.Until(expiresAt) > defaultTokenRenewMargin {
+		return nil
+	}
+	secret, err := vp.client.Auth().Token().RenewSelfWithContext(ctx, 0)
+	if err == nil && secret != nil && secret.Auth != nil {
+		ttl := time.Duration(secret.Auth.LeaseDuration) * time.Second
+		vp.setToken(secret.Auth.ClientToken, time.Now().Add(ttl))
+		return nil
+	}
+	// Renewal failed (token may be non-renewable or already expired); fall
+	// back to a fresh k8s auth login.
+	return vp.k8sLogin(ctx)
+}
+
+// Get implements config.SecretProvider. id must be of the form "path#key".
+func (vp *VaultProvider) Get(ctx context.Context, id string) ([]byte, error) {
+	path, key, ok := strings.Cut(id, "#")
+	if !ok || path == "" || key == "" {
+		return nil, fmt.Errorf("malformed vault secret id (expected path#key): %v", id)
+	}
+	if err := vp.ensureValidToken(ctx); err != nil {
+		return nil, errors.Wrap(err, "error ensuring valid vault token")
+	}
+	secret, err := vp.client.Logical().ReadWithContext(ctx, path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading vault secret at %v", path)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("no vault secret found at %v", path)
+	}
+	data := secret.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		// KV v2 secrets engine nests the actual fields under "data".
+		data = nested
+	}
+	v, ok := data[key]
+	if !ok {
+		return nil, fmt.Errorf("key %v not found in vault secret at %v", key, path)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("key %v in vault secret at %v is not a string", key, path)
+	}
+	return []byte(s), nil
+}
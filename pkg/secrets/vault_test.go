@@ -0,0 +1,133 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dollarshaveclub/acyl/pkg/config"
+)
+
+// fakeVault is a minimal stand-in for Vault's HTTP API, implementing just
+// enough of the k8s auth login, token renew-self and secret read endpoints
+// to exercise VaultProvider's renewal/fallback-login logic end to end.
+type fakeVault struct {
+	loginCount  int
+	renewFails  bool
+	renewCalled bool
+}
+
+func (f *fakeVault) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/auth/kubernetes/login", func(w http.ResponseWriter, r *http.Request) {
+		f.loginCount++
+		writeVaultAuth(w, "login-token", 1)
+	})
+	mux.HandleFunc("/v1/auth/token/renew-self", func(w http.ResponseWriter, r *http.Request) {
+		f.renewCalled = true
+		if f.renewFails {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		writeVaultAuth(w, "renewed-token", 3600)
+	})
+	mux.HandleFunc("/v1/secret/data/foo", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"password": "hunter2",
+			},
+		})
+	})
+	return mux
+}
+
+func writeVaultAuth(w http.ResponseWriter, token string, leaseSeconds int) {
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"auth": map[string]interface{}{
+			"client_token":   token,
+			"lease_duration": leaseSeconds,
+		},
+	})
+}
+
+func newTestVaultProvider(t *testing.T, srv *httptest.Server) *VaultProvider {
+	t.Helper()
+	jwtPath := filepath.Join(t.TempDir(), "jwt")
+	if err := os.WriteFile(jwtPath, []byte("fake-jwt"), 0600); err != nil {
+		t.Fatalf("error writing fake jwt: %v", err)
+	}
+	vp, err := NewVaultProvider(context.Background(), config.VaultConfig{
+		Addr:       srv.URL,
+		K8sAuth:    true,
+		K8sJWTPath: jwtPath,
+		K8sRole:    "acyl",
+	})
+	if err != nil {
+		t.Fatalf("NewVaultProvider returned unexpected error: %v", err)
+	}
+	return vp
+}
+
+func TestVaultProviderInitialLogin(t *testing.T) {
+	fv := &fakeVault{}
+	srv := httptest.NewServer(fv.handler())
+	defer srv.Close()
+
+	vp := newTestVaultProvider(t, srv)
+	if fv.loginCount != 1 {
+		t.Fatalf("expected 1 login on construction, got %v", fv.loginCount)
+	}
+	if vp.token != "login-token" {
+		t.Fatalf("unexpected token after initial login: %v", vp.token)
+	}
+}
+
+func TestVaultProviderRenewsNearExpiry(t *testing.T) {
+	fv := &fakeVault{}
+	srv := httptest.NewServer(fv.handler())
+	defer srv.Close()
+
+	vp := newTestVaultProvider(t, srv)
+	// Force the token to look like it's about to expire.
+	vp.setToken(vp.token, time.Now().Add(time.Second))
+
+	if _, err := vp.Get(context.Background(), "secret/data/foo#password"); err != nil {
+		t.Fatalf("Get returned unexpected error: %v", err)
+	}
+	if !fv.renewCalled {
+		t.Fatal("expected renew-self to be called")
+	}
+	if vp.token != "renewed-token" {
+		t.Fatalf("expected token to be replaced by renewal, got: %v", vp.token)
+	}
+	if fv.loginCount != 1 {
+		t.Fatalf("expected no additional k8s login when renewal succeeds, got %v logins", fv.loginCount)
+	}
+}
+
+func TestVaultProviderFallsBackToLoginWhenRenewalFails(t *testing.T) {
+	fv := &fakeVault{renewFails: true}
+	srv := httptest.NewServer(fv.handler())
+	defer srv.Close()
+
+	vp := newTestVaultProvider(t, srv)
+	vp.setToken(vp.token, time.Now().Add(time.Second))
+
+	if _, err := vp.Get(context.Background(), "secret/data/foo#password"); err != nil {
+		t.Fatalf("Get returned unexpected error: %v", err)
+	}
+	if !fv.renewCalled {
+		t.Fatal("expected renew-self to be attempted")
+	}
+	if fv.loginCount != 2 {
+		t.Fatalf("expected a fresh k8s login after failed renewal, got %v logins", fv.loginCount)
+	}
+	if vp.token != "login-token" {
+		t.Fatalf("expected token from fallback login, got: %v", vp.token)
+	}
+}
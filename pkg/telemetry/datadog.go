@@ -0,0 +1,43 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	ddtracer "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// datadogTracer adapts the Datadog APM tracer to the Tracer interface, so
+// existing Datadog-instrumented deployments keep working without the rest of
+// the codebase importing dd-trace-go directly.
+type datadogTracer struct{}
+
+func newDatadogTracer(serviceName string) (Tracer, ShutdownFunc, error) {
+	ddtracer.Start(ddtracer.WithService(serviceName))
+	shutdown := func(context.Context) error {
+		ddtracer.Stop()
+		return nil
+	}
+	return datadogTracer{}, shutdown, nil
+}
+
+func (datadogTracer) StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, Span) {
+	ddSpan, ddCtx := ddtracer.StartSpanFromContext(ctx, name)
+	for _, a := range attrs {
+		ddSpan.SetTag(string(a.Key), a.Value.AsInterface())
+	}
+	return ddCtx, ddSpanAdapter{ddSpan}
+}
+
+// ddSpanAdapter adapts a Datadog ddtrace.Span to our Span interface.
+type ddSpanAdapter struct {
+	span ddtracer.Span
+}
+
+func (d ddSpanAdapter) End() { d.span.Finish() }
+
+func (d ddSpanAdapter) SetAttributes(attrs ...attribute.KeyValue) {
+	for _, a := range attrs {
+		d.span.SetTag(string(a.Key), a.Value.AsInterface())
+	}
+}
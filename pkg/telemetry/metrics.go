@@ -0,0 +1,62 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/dollarshaveclub/acyl/pkg/config"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// NewMeterProvider returns an OpenTelemetry MeterProvider for use alongside
+// the Tracer returned by NewTracer, and the http.Handler that should be
+// mounted at /metrics (nil if cfg.MetricsEnabled is false).
+//
+// When cfg.MetricsEnabled is set, the MeterProvider is given a Prometheus
+// reader (go.opentelemetry.io/otel/exporters/prometheus), which registers
+// acyl's OTel instruments into prometheus.DefaultRegisterer so that the
+// returned handler — backed by promhttp.Handler, which serves
+// prometheus.DefaultGatherer — actually reflects what's being collected,
+// rather than just Go runtime stats. When cfg.OTLPEnabled is also set, a
+// second reader additionally pushes the same metrics to cfg.OTLPEndpoint.
+func NewMeterProvider(ctx context.Context, cfg config.TelemetryConfig) (*sdkmetric.MeterProvider, http.Handler, ShutdownFunc, error) {
+	var opts []sdkmetric.Option
+	var handler http.Handler
+
+	if cfg.MetricsEnabled {
+		promReader, err := otelprometheus.New()
+		if err != nil {
+			return nil, nil, nil, errors.Wrap(err, "error creating prometheus metric reader")
+		}
+		opts = append(opts, sdkmetric.WithReader(promReader))
+		handler = promhttp.Handler()
+	}
+
+	if cfg.OTLPEnabled {
+		dialOpts := []grpc.DialOption{grpc.WithBlock()}
+		if cfg.OTLPInsecure {
+			dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		} else {
+			dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(nil)))
+		}
+		conn, err := grpc.DialContext(ctx, cfg.OTLPEndpoint, dialOpts...)
+		if err != nil {
+			return nil, nil, nil, errors.Wrap(err, "error dialing OTLP collector")
+		}
+		exp, err := otlpmetricgrpc.New(ctx, otlpmetricgrpc.WithGRPCConn(conn))
+		if err != nil {
+			return nil, nil, nil, errors.Wrap(err, "error creating OTLP metric exporter")
+		}
+		opts = append(opts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exp)))
+	}
+
+	mp := sdkmetric.NewMeterProvider(opts...)
+	return mp, handler, mp.Shutdown, nil
+}
@@ -0,0 +1,79 @@
+package telemetry
+
+import (
+	"context"
+
+	"github.com/dollarshaveclub/acyl/pkg/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// otelTracer is the OpenTelemetry-backed Tracer implementation.
+type otelTracer struct {
+	tracer trace.Tracer
+}
+
+func (o otelTracer) StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, Span) {
+	newCtx, span := o.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+	return newCtx, otelSpanAdapter{span: span}
+}
+
+// otelSpanAdapter adapts a trace.Span to our minimal Span interface.
+// trace.Span.End takes variadic trace.SpanEndOption, so it doesn't
+// structurally satisfy Span's no-arg End; this adapter bridges the two.
+type otelSpanAdapter struct {
+	span trace.Span
+}
+
+func (o otelSpanAdapter) End() { o.span.End() }
+
+func (o otelSpanAdapter) SetAttributes(attrs ...attribute.KeyValue) {
+	o.span.SetAttributes(attrs...)
+}
+
+// newOTelTracer dials cfg.OTLPEndpoint and installs an OpenTelemetry
+// TracerProvider as the global provider, returning a Tracer backed by it.
+func newOTelTracer(ctx context.Context, cfg config.TelemetryConfig) (Tracer, ShutdownFunc, error) {
+	dialOpts := []grpc.DialOption{grpc.WithBlock()}
+	if cfg.OTLPInsecure {
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	} else {
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(nil)))
+	}
+	conn, err := grpc.DialContext(ctx, cfg.OTLPEndpoint, dialOpts...)
+	if err != nil {
+		return nil, nil, wrapErr(err, "otlp")
+	}
+
+	exp, err := otlptrace.New(ctx, otlptracegrpc.NewClient(otlptracegrpc.WithGRPCConn(conn)))
+	if err != nil {
+		return nil, nil, wrapErr(err, "otlp")
+	}
+
+	res, err := resource.New(ctx, resource.WithAttributes(
+		semconv.ServiceNameKey.String(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, nil, wrapErr(err, "otlp")
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+
+	shutdown := func(ctx context.Context) error {
+		return tp.Shutdown(ctx)
+	}
+	return otelTracer{tracer: tp.Tracer("acyl")}, shutdown, nil
+}
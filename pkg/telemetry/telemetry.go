@@ -0,0 +1,95 @@
+// Package telemetry provides tracing and metrics for acyl, routing through a
+// common Tracer interface so that the HTTP server, the Postgres data layer,
+// Kubernetes client calls, Furan build RPCs and Helm operations don't need to
+// import the Datadog or OpenTelemetry SDKs directly. It supports OpenTelemetry
+// OTLP export as a first-class alternative to the existing Datadog
+// integration.
+package telemetry
+
+import (
+	"context"
+
+	"github.com/dollarshaveclub/acyl/pkg/config"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Semantic-convention span attribute keys for acyl-specific context. These
+// are attached to spans by callers via Tracer.StartSpan, e.g.:
+//
+//	ctx, span := tracer.StartSpan(ctx, "build.environment", telemetry.EnvironmentName(envName), telemetry.Repo(repo))
+const (
+	EnvironmentNameKey = attribute.Key("acyl.environment.name")
+	RepoKey            = attribute.Key("acyl.repo")
+	PullRequestKey     = attribute.Key("acyl.pull_request")
+)
+
+// EnvironmentName returns the acyl.environment.name span attribute.
+func EnvironmentName(name string) attribute.KeyValue { return EnvironmentNameKey.String(name) }
+
+// Repo returns the acyl.repo span attribute, e.g. "dollarshaveclub/acyl".
+func Repo(repo string) attribute.KeyValue { return RepoKey.String(repo) }
+
+// PullRequest returns the acyl.pull_request span attribute.
+func PullRequest(pr uint) attribute.KeyValue { return PullRequestKey.Int64(int64(pr)) }
+
+// Span is the subset of behavior acyl code needs from a started span,
+// regardless of backend. It is deliberately minimal (rather than
+// go.opentelemetry.io/otel/trace.Span, which embeds an unexported marker
+// interface specifically to prevent non-SDK types such as our Datadog
+// adapter from implementing it) so that both the OTel and Datadog backends
+// can satisfy it directly.
+type Span interface {
+	// End completes the span.
+	End()
+	// SetAttributes attaches additional key/value attributes to the span.
+	SetAttributes(attrs ...attribute.KeyValue)
+}
+
+// Tracer starts spans without callers needing to know whether the active
+// backend is OpenTelemetry or Datadog.
+type Tracer interface {
+	// StartSpan starts a new span named name as a child of any span found in
+	// ctx, returning a context carrying the new span plus the span itself so
+	// the caller can set attributes and End it.
+	StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, Span)
+}
+
+// ShutdownFunc flushes and releases any resources held by a Tracer
+// constructed by NewTracer.
+type ShutdownFunc func(context.Context) error
+
+// NewTracer constructs the Tracer selected by cfg: when cfg.OTLPEnabled is
+// set, an OpenTelemetry Tracer exporting to cfg.OTLPEndpoint; otherwise, if
+// datadogServiceName is non-empty, a Datadog-backed Tracer for back-compat.
+// If neither is configured, NewTracer returns a no-op Tracer.
+func NewTracer(ctx context.Context, cfg config.TelemetryConfig, datadogServiceName string) (Tracer, ShutdownFunc, error) {
+	switch {
+	case cfg.OTLPEnabled:
+		return newOTelTracer(ctx, cfg)
+	case datadogServiceName != "":
+		return newDatadogTracer(datadogServiceName)
+	default:
+		return noopTracer{}, func(context.Context) error { return nil }, nil
+	}
+}
+
+type noopTracer struct{}
+
+func (noopTracer) StartSpan(ctx context.Context, _ string, _ ...attribute.KeyValue) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) End()                                      {}
+func (noopSpan) SetAttributes(attrs ...attribute.KeyValue) {}
+
+// wrapErr is a small helper so the OTel tracer constructor reports failures
+// with consistent context.
+func wrapErr(err error, backend string) error {
+	if err == nil {
+		return nil
+	}
+	return errors.Wrapf(err, "error initializing %v tracer", backend)
+}
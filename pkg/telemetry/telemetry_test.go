@@ -0,0 +1,67 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dollarshaveclub/acyl/pkg/config"
+)
+
+func TestSemanticAttributes(t *testing.T) {
+	tests := []struct {
+		name          string
+		attr          func() (key string, value string)
+		expectedKey   string
+		expectedValue string
+	}{
+		{
+			name: "environment name",
+			attr: func() (string, string) {
+				kv := EnvironmentName("my-env")
+				return string(kv.Key), kv.Value.AsString()
+			},
+			expectedKey:   "acyl.environment.name",
+			expectedValue: "my-env",
+		},
+		{
+			name: "repo",
+			attr: func() (string, string) {
+				kv := Repo("dollarshaveclub/acyl")
+				return string(kv.Key), kv.Value.AsString()
+			},
+			expectedKey:   "acyl.repo",
+			expectedValue: "dollarshaveclub/acyl",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			key, value := tc.attr()
+			if key != tc.expectedKey {
+				t.Fatalf("key = %v, expected %v", key, tc.expectedKey)
+			}
+			if value != tc.expectedValue {
+				t.Fatalf("value = %v, expected %v", value, tc.expectedValue)
+			}
+		})
+	}
+}
+
+func TestNewMeterProviderMetricsHandler(t *testing.T) {
+	_, handler, shutdown, err := NewMeterProvider(context.Background(), config.TelemetryConfig{})
+	if err != nil {
+		t.Fatalf("NewMeterProvider returned unexpected error: %v", err)
+	}
+	if handler != nil {
+		t.Fatal("expected nil handler when MetricsEnabled is false")
+	}
+	defer shutdown(context.Background())
+
+	_, handler, shutdown, err = NewMeterProvider(context.Background(), config.TelemetryConfig{MetricsEnabled: true})
+	if err != nil {
+		t.Fatalf("NewMeterProvider returned unexpected error: %v", err)
+	}
+	if handler == nil {
+		t.Fatal("expected non-nil handler when MetricsEnabled is true")
+	}
+	defer shutdown(context.Background())
+}